@@ -0,0 +1,70 @@
+// Command btc-api runs the Bitcoin node API server: it wires together the
+// RPC client, metrics collector and HTTP server, then serves forever.
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/tonyrishwain/btc-api/internal/api"
+	"github.com/tonyrishwain/btc-api/internal/bitcoinrpc"
+	"github.com/tonyrishwain/btc-api/internal/metrics"
+)
+
+func main() {
+	collector := metrics.NewCollector(prometheus.DefaultRegisterer)
+
+	cfg := bitcoinrpc.Config{
+		Host: os.Getenv("BITCOIN_RPC_HOST"),
+		User: os.Getenv("BITCOIN_RPC_USER"),
+		Pass: os.Getenv("BITCOIN_RPC_PASSWORD"),
+	}
+	peerAddrs := bitcoinrpc.ParsePeerList(os.Getenv("BITCOIN_P2P_PEERS"))
+
+	client, dispatcher, shutdown, err := bitcoinrpc.NewClient(cfg, peerAddrs, collector)
+	if err != nil {
+		log.Fatalf("Failed to create RPC client after 5 attempts: %v", err)
+	}
+	defer shutdown()
+
+	// Verify connection by getting the current block count
+	blockCount, err := client.GetBlockCount()
+	if err != nil {
+		log.Printf("Failed to get block count: %v", err)
+		log.Println("Continuing execution. Some functionality may be limited.")
+	} else {
+		log.Printf("Successfully connected to Bitcoin node. Current block count: %d", blockCount)
+	}
+
+	server, err := api.NewServer(client, dispatcher, collector)
+	if err != nil {
+		log.Fatalf("Failed to create API server: %v", err)
+	}
+
+	// Seed the threshold accounting window from the current chain tip before
+	// switching over to push-based updates.
+	if err := server.SeedWindow(); err != nil {
+		log.Printf("Failed to seed initial block window: %v", err)
+	}
+
+	// Start the ZMQ notification client, which replaces the old one-minute
+	// polling loop with a push-based feed driven by bitcoind.
+	notifier := bitcoinrpc.NewNotificationClient(
+		os.Getenv("ZMQ_BLOCK_ENDPOINT"),
+		os.Getenv("ZMQ_TX_ENDPOINT"),
+		client,
+		collector,
+		server.OnNewBlock,
+	)
+	go notifier.Run()
+
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux, promhttp.Handler())
+
+	log.Println("Starting server on 0.0.0.0:8080")
+	log.Fatal(http.ListenAndServe("0.0.0.0:8080", mux))
+}
@@ -0,0 +1,496 @@
+// Package api implements the HTTP surface of the Bitcoin node API server:
+// chain status, transaction summaries, Prometheus metrics, and node health.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/tonyrishwain/btc-api/internal/bitcoinrpc"
+	"github.com/tonyrishwain/btc-api/internal/metrics"
+)
+
+// windowSize is the number of trailing blocks the threshold accounting covers.
+const windowSize = 25
+
+// blockCacheSize bounds the number of decoded blocks kept in memory.
+const blockCacheSize = 1024
+
+// defaultFetchConcurrency is the default number of blocks fetched in
+// parallel when summarizing a range of blocks.
+const defaultFetchConcurrency = 8
+
+// maxRescanWindowBlocks bounds how many blocks a single /getTransactionsSummary
+// request may cover, so a misconfigured window can't trigger an unbounded scan.
+const maxRescanWindowBlocks = 2016
+
+// Server holds everything the HTTP handlers need: the Bitcoin RPC client,
+// the pruned-block fallback dispatcher, the Prometheus collectors, and the
+// block cache and rolling window used by the transaction summary endpoints.
+type Server struct {
+	Client     bitcoinrpc.BitcoinClient
+	Dispatcher *bitcoinrpc.PrunedBlockDispatcher
+	Metrics    *metrics.Collector
+
+	blockCache      *lru.Cache
+	heightHashCache *lru.Cache
+
+	windowMu sync.Mutex
+	window   []blockWindowEntry
+}
+
+// blockWindowEntry holds the threshold accounting for a single block so the
+// rolling totals can be updated incrementally as new blocks arrive instead of
+// re-scanning the whole window on every update.
+type blockWindowEntry struct {
+	height int64
+	hash   chainhash.Hash
+	txs    int
+	volume float64
+}
+
+// NewServer builds a Server around the given Bitcoin client, pruned-block
+// dispatcher and metrics collector.
+func NewServer(client bitcoinrpc.BitcoinClient, dispatcher *bitcoinrpc.PrunedBlockDispatcher, m *metrics.Collector) (*Server, error) {
+	blockCache, err := lru.New(blockCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("creating block cache: %w", err)
+	}
+	heightHashCache, err := lru.New(blockCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("creating height->hash cache: %w", err)
+	}
+
+	return &Server{
+		Client:          client,
+		Dispatcher:      dispatcher,
+		Metrics:         m,
+		blockCache:      blockCache,
+		heightHashCache: heightHashCache,
+	}, nil
+}
+
+// RegisterRoutes wires the server's handlers, along with promhttp's /metrics
+// handler, onto mux.
+func (s *Server) RegisterRoutes(mux *http.ServeMux, metricsHandler http.Handler) {
+	mux.HandleFunc("/chainStatus", s.ChainStatusHandler)
+	mux.HandleFunc("/getTransactionsSummary", s.GetTransactionsSummaryHandler)
+	mux.Handle("/metrics", metricsHandler)
+	mux.HandleFunc("/summary", s.SummaryHandler)
+	mux.HandleFunc("/nodeInfo", s.NodeInfoHandler)
+}
+
+// SeedWindow populates the threshold accounting window from the current
+// chain tip via RPC, so the gauges are already warm by the time the first
+// ZMQ notification arrives.
+func (s *Server) SeedWindow() error {
+	blockCount, err := s.Client.GetBlockCount()
+	if err != nil {
+		return err
+	}
+	s.Metrics.BitcoinNodeBlockHeight.Set(float64(blockCount))
+	s.Metrics.BitcoinNodeConnectionStatus.Set(1)
+
+	s.windowMu.Lock()
+	defer s.windowMu.Unlock()
+
+	for height := blockCount - windowSize + 1; height <= blockCount; height++ {
+		if height < 0 {
+			continue
+		}
+		blockHash, err := s.Client.GetBlockHash(height)
+		if err != nil {
+			log.Printf("Error getting block hash for height %d: %v", height, err)
+			continue
+		}
+		block, err := s.Client.GetBlock(blockHash)
+		if err != nil {
+			log.Printf("Error getting block for hash %s: %v", blockHash, err)
+			continue
+		}
+		s.pushWindowEntry(summarizeBlock(height, *blockHash, block))
+	}
+	s.recomputeWindowTotals()
+	return nil
+}
+
+// summarizeBlock computes the threshold-0 transaction accounting for a
+// single block.
+func summarizeBlock(height int64, hash chainhash.Hash, block *wire.MsgBlock) blockWindowEntry {
+	entry := blockWindowEntry{height: height, hash: hash}
+	for _, tx := range block.Transactions {
+		var txValue float64
+		for _, out := range tx.TxOut {
+			txValue += float64(out.Value) / 1e8 // Convert satoshis to BTC
+		}
+		if txValue > 0 {
+			entry.txs++
+			entry.volume += txValue
+		}
+	}
+	return entry
+}
+
+// summarizeBlockAboveThreshold is summarizeBlock generalized to an arbitrary
+// per-output threshold: only outputs strictly above threshold count toward
+// the total.
+func summarizeBlockAboveThreshold(height int64, hash chainhash.Hash, block *wire.MsgBlock, threshold float64) blockWindowEntry {
+	entry := blockWindowEntry{height: height, hash: hash}
+	for _, tx := range block.Transactions {
+		var txValue float64
+		for _, out := range tx.TxOut {
+			value := float64(out.Value) / 1e8 // Convert satoshis to BTC
+			if value > threshold {
+				txValue += value
+			}
+		}
+		if txValue > 0 {
+			entry.txs++
+			entry.volume += txValue
+		}
+	}
+	return entry
+}
+
+// pushWindowEntry appends a block to the window, evicting the oldest entry
+// once the window exceeds windowSize blocks. Callers must hold s.windowMu.
+func (s *Server) pushWindowEntry(entry blockWindowEntry) {
+	for i, existing := range s.window {
+		if existing.height == entry.height {
+			s.window[i] = entry
+			return
+		}
+	}
+	s.window = append(s.window, entry)
+	if len(s.window) > windowSize {
+		s.window = s.window[len(s.window)-windowSize:]
+	}
+}
+
+// recomputeWindowTotals sums the current window into the threshold gauges.
+// Callers must hold s.windowMu.
+func (s *Server) recomputeWindowTotals() {
+	var totalTx int
+	var totalBTC float64
+	for _, entry := range s.window {
+		totalTx += entry.txs
+		totalBTC += entry.volume
+	}
+	s.Metrics.TransactionsAboveThresholdTotal.WithLabelValues(metrics.WindowLabel(windowSize)).Set(float64(totalTx))
+	s.Metrics.BTCVolumeAboveThreshold.WithLabelValues(metrics.WindowLabel(windowSize)).Set(totalBTC)
+}
+
+// OnNewBlock is invoked for every block the notification client observes,
+// either from a ZMQ rawblock message or from gap reconciliation. It updates
+// the rolling window and gauges without re-scanning the whole chain tip.
+func (s *Server) OnNewBlock(height int64, hash chainhash.Hash, block *wire.MsgBlock) {
+	s.windowMu.Lock()
+	defer s.windowMu.Unlock()
+
+	s.pushWindowEntry(summarizeBlock(height, hash, block))
+	s.recomputeWindowTotals()
+
+	s.Metrics.BitcoinNodeBlockHeight.Set(float64(height))
+	s.Metrics.BitcoinNodeConnectionStatus.Set(1)
+}
+
+// fetchBlockByHeight resolves a block by height, serving from blockCache and
+// heightHashCache where possible and falling back to RPC on a miss. Cache
+// population relies on the underlying lru.Cache's own internal locking, so
+// callers may call this concurrently without an external mutex.
+func (s *Server) fetchBlockByHeight(height int64) (*chainhash.Hash, *wire.MsgBlock, error) {
+	var blockHash *chainhash.Hash
+	if cached, ok := s.heightHashCache.Get(height); ok {
+		blockHash = cached.(*chainhash.Hash)
+	} else {
+		var err error
+		blockHash, err = s.Client.GetBlockHash(height)
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting block hash for height %d: %w", height, err)
+		}
+		s.heightHashCache.Add(height, blockHash)
+	}
+
+	if cached, ok := s.blockCache.Get(*blockHash); ok {
+		s.Metrics.BitcoinBlockCacheHitsTotal.Inc()
+		s.Metrics.BitcoinBlockCacheSize.Set(float64(s.blockCache.Len()))
+		return blockHash, cached.(*wire.MsgBlock), nil
+	}
+
+	s.Metrics.BitcoinBlockCacheMissesTotal.Inc()
+	block, err := s.Client.GetBlock(blockHash)
+	if err != nil {
+		return blockHash, nil, fmt.Errorf("getting block for hash %s: %w", blockHash, err)
+	}
+	s.blockCache.Add(*blockHash, block)
+	s.Metrics.BitcoinBlockCacheSize.Set(float64(s.blockCache.Len()))
+	return blockHash, block, nil
+}
+
+// blockSummary is the per-block breakdown returned when /getTransactionsSummary
+// is called with detailed=true.
+type blockSummary struct {
+	Height    int64   `json:"height"`
+	Hash      string  `json:"hash"`
+	TxCount   int     `json:"tx_count"`
+	BTCVolume float64 `json:"btc_volume"`
+}
+
+// summarizeRange fetches every block in [fromHeight, toHeight] concurrently
+// (bounded by defaultFetchConcurrency and served from the block cache where
+// possible) and streams a blockSummary for each one over the returned
+// channel as soon as it's ready, rather than buffering the whole window in
+// memory. A block that fails to fetch is logged and gets no summary. The
+// channel is closed once every block has been processed.
+func (s *Server) summarizeRange(ctx context.Context, fromHeight, toHeight int64, threshold float64) <-chan blockSummary {
+	out := make(chan blockSummary, defaultFetchConcurrency)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(defaultFetchConcurrency)
+
+	go func() {
+		for height := fromHeight; height <= toHeight; height++ {
+			height := height
+			g.Go(func() error {
+				blockHash, block, err := s.fetchBlockByHeight(height)
+				if err != nil {
+					log.Print(err)
+					return nil
+				}
+				entry := summarizeBlockAboveThreshold(height, *blockHash, block, threshold)
+				select {
+				case out <- blockSummary{Height: height, Hash: blockHash.String(), TxCount: entry.txs, BTCVolume: entry.volume}:
+				case <-gctx.Done():
+				}
+				return nil
+			})
+		}
+		_ = g.Wait() // individual fetch errors are logged and skipped above
+		close(out)
+	}()
+
+	return out
+}
+
+// resolveWindow turns a /getTransactionsSummary request's query parameters
+// into a concrete [fromHeight, toHeight] block range. It supports, in order
+// of precedence: an explicit from_height/to_height pair, a since=RFC3339
+// timestamp, or a blocks=N count defaulting to windowSize. It returns a
+// descriptive error when the request is malformed or the resulting window
+// exceeds maxRescanWindowBlocks.
+func (s *Server) resolveWindow(r *http.Request) (fromHeight, toHeight int64, err error) {
+	tip, err := s.Client.GetBlockCount()
+	if err != nil {
+		return 0, 0, fmt.Errorf("getting block count: %w", err)
+	}
+
+	query := r.URL.Query()
+	switch {
+	case query.Get("from_height") != "" || query.Get("to_height") != "":
+		fromHeight, err = strconv.ParseInt(query.Get("from_height"), 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid from_height: %w", err)
+		}
+		toHeight, err = strconv.ParseInt(query.Get("to_height"), 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid to_height: %w", err)
+		}
+		if fromHeight > toHeight {
+			return 0, 0, fmt.Errorf("from_height must not be greater than to_height")
+		}
+
+	case query.Get("since") != "":
+		since, err := time.Parse(time.RFC3339, query.Get("since"))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid since (expected RFC3339): %w", err)
+		}
+		fromHeight, err = s.resolveSinceHeight(since, tip)
+		if err != nil {
+			return 0, 0, fmt.Errorf("resolving since: %w", err)
+		}
+		toHeight = tip
+
+	default:
+		blocks := int64(windowSize)
+		if raw := query.Get("blocks"); raw != "" {
+			blocks, err = strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return 0, 0, fmt.Errorf("invalid blocks: %w", err)
+			}
+		}
+		if blocks < 1 || blocks > maxRescanWindowBlocks {
+			return 0, 0, fmt.Errorf("blocks must be between 1 and %d", maxRescanWindowBlocks)
+		}
+		toHeight = tip
+		fromHeight = tip - blocks + 1
+	}
+
+	if fromHeight < 0 {
+		fromHeight = 0
+	}
+	if toHeight > tip {
+		toHeight = tip
+	}
+	if fromHeight > tip {
+		return 0, 0, fmt.Errorf("from_height %d is beyond the current chain tip %d", fromHeight, tip)
+	}
+	if fromHeight > toHeight {
+		return 0, 0, fmt.Errorf("from_height must not be greater than to_height")
+	}
+	if toHeight-fromHeight+1 > maxRescanWindowBlocks {
+		return 0, 0, fmt.Errorf("requested window of %d blocks exceeds the maximum of %d", toHeight-fromHeight+1, maxRescanWindowBlocks)
+	}
+	return fromHeight, toHeight, nil
+}
+
+// resolveSinceHeight binary-searches for the lowest block height at or
+// after the given time, reading each probed block's header timestamp from
+// the blocks fetched (and cached) via fetchBlockByHeight. If every block up
+// to tip is older than since, it returns tip.
+func (s *Server) resolveSinceHeight(since time.Time, tip int64) (int64, error) {
+	lo, hi := int64(0), tip
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		_, block, err := s.fetchBlockByHeight(mid)
+		if err != nil {
+			return 0, err
+		}
+		if block.Header.Timestamp.Before(since) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// ChainStatusHandler responds with the current chain status.
+func (s *Server) ChainStatusHandler(w http.ResponseWriter, r *http.Request) {
+	timer := prometheus.NewTimer(s.Metrics.HTTPRequestDuration.WithLabelValues("/chainStatus"))
+	defer timer.ObserveDuration()
+	s.Metrics.HTTPRequestsTotal.WithLabelValues("/chainStatus").Inc()
+
+	blockCount, err := s.Client.GetBlockCount()
+	if err != nil {
+		log.Printf("Error getting block count: %v", err)
+		http.Error(w, fmt.Sprintf("Error getting block count: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := struct {
+		Chain           string `json:"chain"`
+		LastBlockHeight int64  `json:"last_block_height"`
+	}{
+		Chain:           "OK",
+		LastBlockHeight: blockCount,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// NodeInfoHandler responds with the node's prune height and the health of
+// the pruned-block P2P fallback dispatcher.
+func (s *Server) NodeInfoHandler(w http.ResponseWriter, r *http.Request) {
+	timer := prometheus.NewTimer(s.Metrics.HTTPRequestDuration.WithLabelValues("/nodeInfo"))
+	defer timer.ObserveDuration()
+	s.Metrics.HTTPRequestsTotal.WithLabelValues("/nodeInfo").Inc()
+
+	var pruneHeight int64
+	var pruned bool
+	if info, err := s.Client.GetBlockChainInfo(); err != nil {
+		log.Printf("Error getting blockchain info: %v", err)
+	} else {
+		pruned = info.Pruned
+		pruneHeight = int64(info.PruneHeight)
+	}
+
+	response := struct {
+		Pruned               bool  `json:"pruned"`
+		PruneHeight          int64 `json:"prune_height"`
+		FallbackPeers        int   `json:"fallback_peers"`
+		FallbackDispatcherUp bool  `json:"fallback_dispatcher_up"`
+	}{
+		Pruned:               pruned,
+		PruneHeight:          pruneHeight,
+		FallbackPeers:        s.Dispatcher.PeerCount(),
+		FallbackDispatcherUp: s.Dispatcher.Healthy(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetTransactionsSummaryHandler responds with a summary of transactions
+// above a specified threshold over a configurable block window.
+func (s *Server) GetTransactionsSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	timer := prometheus.NewTimer(s.Metrics.HTTPRequestDuration.WithLabelValues("/getTransactionsSummary"))
+	defer timer.ObserveDuration()
+	s.Metrics.HTTPRequestsTotal.WithLabelValues("/getTransactionsSummary").Inc()
+
+	threshold, _ := strconv.ParseFloat(r.URL.Query().Get("threshold"), 64)
+	detailed := r.URL.Query().Get("detailed") == "true"
+
+	fromHeight, toHeight, err := s.resolveWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summaries := s.summarizeRange(r.Context(), fromHeight, toHeight, threshold)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if detailed {
+		w.Write([]byte("["))
+		enc := json.NewEncoder(w)
+		first := true
+		for summary := range summaries {
+			if !first {
+				w.Write([]byte(","))
+			}
+			first = false
+			enc.Encode(summary)
+		}
+		w.Write([]byte("]"))
+		return
+	}
+
+	var totalTx int
+	var totalBTC float64
+	for summary := range summaries {
+		totalTx += summary.TxCount
+		totalBTC += summary.BTCVolume
+	}
+
+	// transactions_above_threshold_total/btc_volume_above_threshold are owned
+	// by OnNewBlock's live, threshold-0 accounting of the rolling windowSize
+	// window; this handler serves arbitrary caller-chosen windows and
+	// thresholds ad hoc and must not stomp that gauge with its own results.
+	response := struct {
+		TotalTransactions int     `json:"total_transactions"`
+		TotalBTC          float64 `json:"total_btc"`
+	}{
+		TotalTransactions: totalTx,
+		TotalBTC:          totalBTC,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// SummaryHandler provides a custom, human-readable summary of selected metrics.
+func (s *Server) SummaryHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(s.Metrics.FormatSummary()))
+}
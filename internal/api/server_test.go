@@ -0,0 +1,457 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/tonyrishwain/btc-api/internal/bitcoinrpc"
+	"github.com/tonyrishwain/btc-api/internal/metrics"
+)
+
+// mockClient is a mock of the bitcoinrpc.BitcoinClient interface.
+type mockClient struct {
+	mockGetBlockCount     func() (int64, error)
+	mockGetBlockHash      func(blockHeight int64) (*chainhash.Hash, error)
+	mockGetBlock          func(blockHash *chainhash.Hash) (*wire.MsgBlock, error)
+	mockGetBlockChainInfo func() (*btcjson.GetBlockChainInfoResult, error)
+}
+
+func (m *mockClient) GetBlockCount() (int64, error) {
+	return m.mockGetBlockCount()
+}
+
+func (m *mockClient) GetBlockHash(blockHeight int64) (*chainhash.Hash, error) {
+	return m.mockGetBlockHash(blockHeight)
+}
+
+func (m *mockClient) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	return m.mockGetBlock(blockHash)
+}
+
+func (m *mockClient) GetBlockChainInfo() (*btcjson.GetBlockChainInfoResult, error) {
+	if m.mockGetBlockChainInfo == nil {
+		return &btcjson.GetBlockChainInfoResult{}, nil
+	}
+	return m.mockGetBlockChainInfo()
+}
+
+// singleTxMockClient returns a mock client whose chain tip is at height
+// 12345 and whose blocks each contain a single 1 BTC transaction, with a
+// height-dependent hash so the block cache doesn't dedupe across heights.
+func singleTxMockClient() *mockClient {
+	return &mockClient{
+		mockGetBlockCount: func() (int64, error) {
+			return 12345, nil
+		},
+		mockGetBlockHash: func(blockHeight int64) (*chainhash.Hash, error) {
+			hash := chainhash.HashH([]byte(fmt.Sprintf("window-test-%d", blockHeight)))
+			return &hash, nil
+		},
+		mockGetBlock: func(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+			tx := wire.NewMsgTx(wire.TxVersion)
+			tx.AddTxOut(wire.NewTxOut(100000000, []byte{})) // 1 BTC output
+			emptyHash := chainhash.Hash{}
+			block := wire.NewMsgBlock(wire.NewBlockHeader(0, &emptyHash, &emptyHash, 0, 0))
+			block.AddTransaction(tx)
+			return block, nil
+		},
+	}
+}
+
+// newTestServer builds a Server (and its own isolated metrics registry) over
+// the given client and starts an httptest.Server exposing its routes.
+func newTestServer(t *testing.T, client bitcoinrpc.BitcoinClient) (*Server, *httptest.Server) {
+	t.Helper()
+	collector := metrics.NewCollector(prometheus.NewRegistry())
+	server, err := NewServer(client, bitcoinrpc.NewPrunedBlockDispatcher(nil, nil), collector)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux, promhttp.HandlerFor(prometheusGatherer(collector), promhttp.HandlerOpts{}))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return server, ts
+}
+
+// prometheusGatherer builds a registry exposing every collector that
+// metrics.NewCollector registers, for use by promhttp.HandlerFor in
+// tests that exercise /metrics through an httptest.Server.
+func prometheusGatherer(c *metrics.Collector) prometheus.Gatherer {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		c.HTTPRequestsTotal,
+		c.HTTPRequestDuration,
+		c.BitcoinNodeBlockHeight,
+		c.BitcoinNodeConnectionStatus,
+		c.BitcoinZMQConnectionStatus,
+		c.TransactionsAboveThresholdTotal,
+		c.BTCVolumeAboveThreshold,
+		c.BitcoinRPCLatency,
+		c.BitcoinRPCErrorsTotal,
+		c.BitcoinRPCInFlight,
+		c.BitcoinBlockCacheHitsTotal,
+		c.BitcoinBlockCacheMissesTotal,
+		c.BitcoinBlockCacheSize,
+		c.BitcoinPrunedFallbackTotal,
+		c.BitcoinPrunedFallbackErrorsTotal,
+	)
+	return reg
+}
+
+func TestMetricsHandler(t *testing.T) {
+	server, ts := newTestServer(t, singleTxMockClient())
+	server.Metrics.BitcoinNodeBlockHeight.Set(12345)
+	server.Metrics.BitcoinNodeConnectionStatus.Set(1)
+	server.Metrics.TransactionsAboveThresholdTotal.WithLabelValues("25").Set(100)
+	server.Metrics.BTCVolumeAboveThreshold.WithLabelValues("25").Set(50)
+	server.Metrics.HTTPRequestsTotal.WithLabelValues("/chainStatus").Add(5)
+	server.Metrics.HTTPRequestsTotal.WithLabelValues("/getTransactionsSummary").Add(3)
+	server.Metrics.HTTPRequestDuration.WithLabelValues("/chainStatus").Observe(0.1)
+	server.Metrics.HTTPRequestDuration.WithLabelValues("/getTransactionsSummary").Observe(0.2)
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", resp.StatusCode, http.StatusOK)
+	}
+
+	body := readBody(t, resp)
+	expectedMetrics := []string{
+		"bitcoin_node_block_height 12345",
+		"bitcoin_node_connection_status 1",
+		`transactions_above_threshold_total{window="25"} 100`,
+		`btc_volume_above_threshold{window="25"} 50`,
+		`http_requests_total{endpoint="/chainStatus"} 5`,
+		`http_requests_total{endpoint="/getTransactionsSummary"} 3`,
+		`http_request_duration_seconds_sum{endpoint="/chainStatus"}`,
+		`http_request_duration_seconds_sum{endpoint="/getTransactionsSummary"}`,
+	}
+	for _, metric := range expectedMetrics {
+		if !strings.Contains(body, metric) {
+			t.Errorf("Expected metric not found: %s", metric)
+		}
+	}
+}
+
+func TestChainStatusHandler(t *testing.T) {
+	mockClient := &mockClient{
+		mockGetBlockCount: func() (int64, error) {
+			return 12345, nil
+		},
+	}
+	_, ts := newTestServer(t, mockClient)
+
+	resp, err := http.Get(ts.URL + "/chainStatus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", resp.StatusCode, http.StatusOK)
+	}
+
+	expected := map[string]interface{}{
+		"chain":             "OK",
+		"last_block_height": float64(12345),
+	}
+	var got map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["chain"] != expected["chain"] || got["last_block_height"] != expected["last_block_height"] {
+		t.Errorf("handler returned unexpected body: got %v want %v", got, expected)
+	}
+}
+
+func TestGetTransactionsSummaryHandler(t *testing.T) {
+	mockClient := &mockClient{
+		mockGetBlockCount: func() (int64, error) {
+			return 12345, nil
+		},
+		mockGetBlockHash: func(blockHeight int64) (*chainhash.Hash, error) {
+			hash, _ := chainhash.NewHashFromStr("000000000000000000024bead8df69990852c202db0e0097c1a12ea637d7e96d")
+			return hash, nil
+		},
+		mockGetBlock: func(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+			tx := wire.NewMsgTx(wire.TxVersion)
+			tx.AddTxOut(wire.NewTxOut(100000000, []byte{})) // 1 BTC output
+			emptyHash := chainhash.Hash{}
+			block := wire.NewMsgBlock(wire.NewBlockHeader(0, &emptyHash, &emptyHash, 0, 0))
+			block.AddTransaction(tx)
+			return block, nil
+		},
+	}
+	_, ts := newTestServer(t, mockClient)
+
+	resp, err := http.Get(ts.URL + "/getTransactionsSummary?threshold=0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", resp.StatusCode, http.StatusOK)
+	}
+
+	expected := map[string]interface{}{
+		"total_transactions": float64(25),
+		"total_btc":          float64(25),
+	}
+	var got map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["total_transactions"] != expected["total_transactions"] || got["total_btc"] != expected["total_btc"] {
+		t.Errorf("handler returned unexpected body: got %v want %v", got, expected)
+	}
+}
+
+func TestGetTransactionsSummaryHandlerBlocksParam(t *testing.T) {
+	_, ts := newTestServer(t, singleTxMockClient())
+
+	resp, err := http.Get(ts.URL + "/getTransactionsSummary?threshold=0.5&blocks=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", resp.StatusCode, http.StatusOK)
+	}
+	var got map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["total_transactions"] != float64(10) || got["total_btc"] != float64(10) {
+		t.Errorf("handler returned unexpected body: got %v", got)
+	}
+}
+
+func TestGetTransactionsSummaryHandlerFromToHeight(t *testing.T) {
+	_, ts := newTestServer(t, singleTxMockClient())
+
+	resp, err := http.Get(ts.URL + "/getTransactionsSummary?threshold=0.5&from_height=12340&to_height=12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", resp.StatusCode, http.StatusOK)
+	}
+	var got map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["total_transactions"] != float64(6) || got["total_btc"] != float64(6) {
+		t.Errorf("handler returned unexpected body: got %v", got)
+	}
+}
+
+func TestGetTransactionsSummaryHandlerDetailed(t *testing.T) {
+	_, ts := newTestServer(t, singleTxMockClient())
+
+	resp, err := http.Get(ts.URL + "/getTransactionsSummary?threshold=0.5&blocks=3&detailed=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", resp.StatusCode, http.StatusOK)
+	}
+
+	var got []blockSummary
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to parse detailed response: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 block summaries, got %d", len(got))
+	}
+	for _, s := range got {
+		if s.TxCount != 1 || s.BTCVolume != 1 {
+			t.Errorf("unexpected block summary: %+v", s)
+		}
+	}
+}
+
+func TestGetTransactionsSummaryHandlerWindowTooLarge(t *testing.T) {
+	_, ts := newTestServer(t, singleTxMockClient())
+
+	resp, err := http.Get(ts.URL + "/getTransactionsSummary?blocks=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestGetTransactionsSummaryHandlerWindowBeyondTip(t *testing.T) {
+	_, ts := newTestServer(t, singleTxMockClient())
+
+	resp, err := http.Get(ts.URL + "/getTransactionsSummary?from_height=20000&to_height=20010")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestGetTransactionsSummaryHandlerDoesNotStompLiveGauge(t *testing.T) {
+	server, ts := newTestServer(t, singleTxMockClient())
+	server.Metrics.TransactionsAboveThresholdTotal.WithLabelValues("25").Set(999)
+	server.Metrics.BTCVolumeAboveThreshold.WithLabelValues("25").Set(999)
+
+	resp, err := http.Get(ts.URL + "/getTransactionsSummary?threshold=1000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", resp.StatusCode, http.StatusOK)
+	}
+
+	if got := testutil.ToFloat64(server.Metrics.TransactionsAboveThresholdTotal.WithLabelValues("25")); got != 999 {
+		t.Errorf("expected transactions_above_threshold_total{window=\"25\"} to remain 999, got %v", got)
+	}
+	if got := testutil.ToFloat64(server.Metrics.BTCVolumeAboveThreshold.WithLabelValues("25")); got != 999 {
+		t.Errorf("expected btc_volume_above_threshold{window=\"25\"} to remain 999, got %v", got)
+	}
+}
+
+func TestSummaryHandler(t *testing.T) {
+	server, ts := newTestServer(t, singleTxMockClient())
+	server.Metrics.BitcoinNodeBlockHeight.Set(12345)
+	server.Metrics.BitcoinNodeConnectionStatus.Set(1)
+	server.Metrics.TransactionsAboveThresholdTotal.WithLabelValues("25").Set(100)
+	server.Metrics.BTCVolumeAboveThreshold.WithLabelValues("25").Set(50)
+
+	resp, err := http.Get(ts.URL + "/summary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", resp.StatusCode, http.StatusOK)
+	}
+
+	body := readBody(t, resp)
+	expectedPrefix := `# Bitcoin Node Status
+bitcoin_node_block_height 12345
+bitcoin_node_connection_status 1
+
+# Transaction Summary
+transactions_above_threshold_total{window="25"} 100
+btc_volume_above_threshold{window="25"} 50
+
+`
+	if !strings.HasPrefix(body, expectedPrefix) {
+		t.Errorf("handler returned unexpected body: got %v want prefix %v", body, expectedPrefix)
+	}
+	if !strings.Contains(body, "# RPC\n") {
+		t.Errorf("handler response missing RPC section: got %v", body)
+	}
+}
+
+func TestFetchBlockByHeightCachesBlocks(t *testing.T) {
+	hashCalls := 0
+	blockCalls := 0
+	hash := chainhash.HashH([]byte("fetch-block-by-height"))
+	mockClient := &mockClient{
+		mockGetBlockHash: func(blockHeight int64) (*chainhash.Hash, error) {
+			hashCalls++
+			return &hash, nil
+		},
+		mockGetBlock: func(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+			blockCalls++
+			emptyHash := chainhash.Hash{}
+			return wire.NewMsgBlock(wire.NewBlockHeader(0, &emptyHash, &emptyHash, 0, 0)), nil
+		},
+	}
+	server, _ := newTestServer(t, mockClient)
+
+	if _, _, err := server.fetchBlockByHeight(100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := server.fetchBlockByHeight(100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if blockCalls != 1 {
+		t.Errorf("expected GetBlock to be called once (second call should hit cache), got %d calls", blockCalls)
+	}
+	if hashCalls != 1 {
+		t.Errorf("expected GetBlockHash to be called once (second call should hit cache), got %d calls", hashCalls)
+	}
+}
+
+func TestGetTransactionsSummaryConcurrentSafety(t *testing.T) {
+	mockClient := &mockClient{
+		mockGetBlockCount: func() (int64, error) {
+			return 1000, nil
+		},
+		mockGetBlockHash: func(blockHeight int64) (*chainhash.Hash, error) {
+			hash := chainhash.HashH([]byte(fmt.Sprintf("height-%d", blockHeight)))
+			return &hash, nil
+		},
+		mockGetBlock: func(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+			tx := wire.NewMsgTx(wire.TxVersion)
+			tx.AddTxOut(wire.NewTxOut(100000000, []byte{})) // 1 BTC output
+			emptyHash := chainhash.Hash{}
+			block := wire.NewMsgBlock(wire.NewBlockHeader(0, &emptyHash, &emptyHash, 0, 0))
+			block.AddTransaction(tx)
+			return block, nil
+		},
+	}
+	_, ts := newTestServer(t, mockClient)
+
+	done := make(chan struct{})
+	for i := 0; i < 20; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			resp, err := http.Get(ts.URL + "/getTransactionsSummary?threshold=0.5")
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			defer resp.Body.Close()
+			var got map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if got["total_transactions"] != float64(25) || got["total_btc"] != float64(25) {
+				t.Errorf("expected (25, 25), got (%v, %v)", got["total_transactions"], got["total_btc"])
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		<-done
+	}
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	return string(body)
+}
@@ -0,0 +1,221 @@
+// Package bitcoinrpc provides the BitcoinClient abstraction used to talk to
+// a bitcoind node, along with decorators that add metrics instrumentation
+// and pruned-node P2P fallback, and a ZMQ-driven notification client.
+package bitcoinrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/tonyrishwain/btc-api/internal/metrics"
+)
+
+// BitcoinClient is the set of bitcoind RPC calls the rest of the server
+// depends on.
+type BitcoinClient interface {
+	GetBlockCount() (int64, error)
+	GetBlockHash(blockHeight int64) (*chainhash.Hash, error)
+	GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error)
+	GetBlockChainInfo() (*btcjson.GetBlockChainInfoResult, error)
+}
+
+// Config holds the connection details for a bitcoind RPC endpoint.
+type Config struct {
+	Host string
+	User string
+	Pass string
+}
+
+// NewClient dials a bitcoind RPC endpoint with retries, and wraps it with
+// metrics instrumentation and pruned-node P2P fallback. It returns the
+// ready-to-use client, the fallback dispatcher (for health reporting), and
+// a shutdown func to release the underlying connection.
+func NewClient(cfg Config, peerAddrs []string, m *metrics.Collector) (client BitcoinClient, dispatcher *PrunedBlockDispatcher, shutdown func(), err error) {
+	connCfg := &rpcclient.ConnConfig{
+		Host:         cfg.Host,
+		User:         cfg.User,
+		Pass:         cfg.Pass,
+		HTTPPostMode: true,
+		DisableTLS:   true,
+	}
+
+	var rpcClient *rpcclient.Client
+	for retries := 0; retries < 5; retries++ {
+		rpcClient, err = rpcclient.New(connCfg, nil)
+		if err == nil {
+			break
+		}
+		log.Printf("Failed to create RPC client (attempt %d/5): %v", retries+1, err)
+		time.Sleep(5 * time.Second)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	dispatcher = NewPrunedBlockDispatcher(peerAddrs, &chaincfg.MainNetParams)
+	client = NewInstrumentedClient(NewPrunedFallbackClient(rpcClient, dispatcher, m), m)
+	shutdown = rpcClient.Shutdown
+	return client, dispatcher, shutdown, nil
+}
+
+// instrumentedClient wraps a BitcoinClient and records per-method latency,
+// error and in-flight metrics around every call, analogous to Blockbook's
+// blockChainWithMetrics decorator.
+type instrumentedClient struct {
+	inner   BitcoinClient
+	metrics *metrics.Collector
+}
+
+// NewInstrumentedClient wraps inner so every RPC call is measured against m.
+func NewInstrumentedClient(inner BitcoinClient, m *metrics.Collector) BitcoinClient {
+	return &instrumentedClient{inner: inner, metrics: m}
+}
+
+// observeRPC runs fn, recording latency, in-flight count and, on failure,
+// an error counter classified by errorClass(err).
+func (c *instrumentedClient) observeRPC(method string, fn func() error) error {
+	c.metrics.BitcoinRPCInFlight.Inc()
+	defer c.metrics.BitcoinRPCInFlight.Dec()
+
+	timer := prometheus.NewTimer(c.metrics.BitcoinRPCLatency.WithLabelValues(method))
+	defer timer.ObserveDuration()
+
+	err := fn()
+	if err != nil {
+		c.metrics.BitcoinRPCErrorsTotal.WithLabelValues(method, errorClass(err)).Inc()
+	}
+	return err
+}
+
+// errorClass buckets an RPC error into a small set of Prometheus label
+// values so the errors_total cardinality stays bounded.
+func errorClass(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded), strings.Contains(err.Error(), "timeout"):
+		return "timeout"
+	case errors.Is(err, rpcclient.ErrClientShutdown), strings.Contains(err.Error(), "connection"):
+		return "connection"
+	case strings.Contains(strings.ToLower(err.Error()), "not found"):
+		return "not_found"
+	default:
+		return "other"
+	}
+}
+
+func (c *instrumentedClient) GetBlockCount() (int64, error) {
+	var count int64
+	err := c.observeRPC("GetBlockCount", func() error {
+		var err error
+		count, err = c.inner.GetBlockCount()
+		return err
+	})
+	return count, err
+}
+
+func (c *instrumentedClient) GetBlockHash(blockHeight int64) (*chainhash.Hash, error) {
+	var hash *chainhash.Hash
+	err := c.observeRPC("GetBlockHash", func() error {
+		var err error
+		hash, err = c.inner.GetBlockHash(blockHeight)
+		return err
+	})
+	return hash, err
+}
+
+func (c *instrumentedClient) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	var block *wire.MsgBlock
+	err := c.observeRPC("GetBlock", func() error {
+		var err error
+		block, err = c.inner.GetBlock(blockHash)
+		return err
+	})
+	return block, err
+}
+
+func (c *instrumentedClient) GetBlockChainInfo() (*btcjson.GetBlockChainInfoResult, error) {
+	var info *btcjson.GetBlockChainInfoResult
+	err := c.observeRPC("GetBlockChainInfo", func() error {
+		var err error
+		info, err = c.inner.GetBlockChainInfo()
+		return err
+	})
+	return info, err
+}
+
+// prunedFallbackClient wraps a BitcoinClient and, when GetBlock fails
+// because the node has pruned the requested block, falls back to fetching
+// it directly from a configured set of Bitcoin P2P peers via dispatcher.
+type prunedFallbackClient struct {
+	inner      BitcoinClient
+	dispatcher *PrunedBlockDispatcher
+	metrics    *metrics.Collector
+}
+
+// NewPrunedFallbackClient wraps inner with pruned-node fallback through
+// dispatcher. If dispatcher has no configured peers, GetBlock behaves
+// exactly like inner.
+func NewPrunedFallbackClient(inner BitcoinClient, dispatcher *PrunedBlockDispatcher, m *metrics.Collector) BitcoinClient {
+	return &prunedFallbackClient{inner: inner, dispatcher: dispatcher, metrics: m}
+}
+
+func (c *prunedFallbackClient) GetBlockCount() (int64, error) { return c.inner.GetBlockCount() }
+
+func (c *prunedFallbackClient) GetBlockHash(blockHeight int64) (*chainhash.Hash, error) {
+	return c.inner.GetBlockHash(blockHeight)
+}
+
+func (c *prunedFallbackClient) GetBlockChainInfo() (*btcjson.GetBlockChainInfoResult, error) {
+	return c.inner.GetBlockChainInfo()
+}
+
+func (c *prunedFallbackClient) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	block, err := c.inner.GetBlock(blockHash)
+	if err == nil || !c.dispatcher.Healthy() || !isPrunedBlockError(err) {
+		return block, err
+	}
+
+	log.Printf("Block %s unavailable on RPC node (pruned), falling back to P2P peers", blockHash)
+	block, ferr := c.dispatcher.FetchBlock(blockHash)
+	if ferr != nil {
+		c.metrics.BitcoinPrunedFallbackErrorsTotal.Inc()
+		return nil, fmt.Errorf("RPC error %q and P2P fallback failed: %w", err, ferr)
+	}
+	c.metrics.BitcoinPrunedFallbackTotal.Inc()
+	return block, nil
+}
+
+// isPrunedBlockError reports whether err looks like bitcoind's "block not
+// available (pruned data)" RPC error.
+func isPrunedBlockError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "pruned") ||
+		strings.Contains(strings.ToLower(err.Error()), "block not available")
+}
+
+// ParsePeerList splits a comma-separated BITCOIN_P2P_PEERS value into a
+// cleaned list of host:port addresses.
+func ParsePeerList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var peers []string
+	for _, addr := range strings.Split(raw, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr != "" {
+			peers = append(peers, addr)
+		}
+	}
+	return peers
+}
@@ -0,0 +1,131 @@
+package bitcoinrpc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/tonyrishwain/btc-api/internal/metrics"
+)
+
+// mockClient is a mock of the BitcoinClient interface.
+type mockClient struct {
+	mockGetBlockCount     func() (int64, error)
+	mockGetBlockHash      func(blockHeight int64) (*chainhash.Hash, error)
+	mockGetBlock          func(blockHash *chainhash.Hash) (*wire.MsgBlock, error)
+	mockGetBlockChainInfo func() (*btcjson.GetBlockChainInfoResult, error)
+}
+
+func (m *mockClient) GetBlockCount() (int64, error) {
+	return m.mockGetBlockCount()
+}
+
+func (m *mockClient) GetBlockHash(blockHeight int64) (*chainhash.Hash, error) {
+	return m.mockGetBlockHash(blockHeight)
+}
+
+func (m *mockClient) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	return m.mockGetBlock(blockHash)
+}
+
+func (m *mockClient) GetBlockChainInfo() (*btcjson.GetBlockChainInfoResult, error) {
+	if m.mockGetBlockChainInfo == nil {
+		return &btcjson.GetBlockChainInfoResult{}, nil
+	}
+	return m.mockGetBlockChainInfo()
+}
+
+func TestInstrumentedClientMetrics(t *testing.T) {
+	collector := metrics.NewCollector(prometheus.NewRegistry())
+
+	callCount := 0
+	mockClient := &mockClient{
+		mockGetBlockCount: func() (int64, error) {
+			callCount++
+			if callCount == 1 {
+				return 0, fmt.Errorf("connection refused")
+			}
+			return 42, nil
+		},
+	}
+	instrumented := NewInstrumentedClient(mockClient, collector)
+
+	if _, err := instrumented.GetBlockCount(); err == nil {
+		t.Fatal("expected an error from the first call")
+	}
+	if count, err := instrumented.GetBlockCount(); err != nil || count != 42 {
+		t.Fatalf("expected (42, nil), got (%d, %v)", count, err)
+	}
+
+	errors := counterValue(t, collector.BitcoinRPCErrorsTotal.WithLabelValues("GetBlockCount", "connection"))
+	if errors != 1 {
+		t.Errorf("expected bitcoin_rpc_errors_total{method=\"GetBlockCount\",class=\"connection\"} to be 1, got %v", errors)
+	}
+
+	samples := histogramSampleCount(t, collector.BitcoinRPCLatency.WithLabelValues("GetBlockCount"))
+	if samples != 2 {
+		t.Errorf("expected bitcoin_rpc_latency_seconds{method=\"GetBlockCount\"} sample count to be 2, got %v", samples)
+	}
+}
+
+// counterValue reads the current value of a Prometheus counter metric.
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := c.Write(&m); err != nil {
+		t.Fatalf("failed to read counter: %v", err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+// histogramSampleCount reads the current sample count of a Prometheus
+// histogram metric.
+func histogramSampleCount(t *testing.T, h prometheus.Observer) uint64 {
+	t.Helper()
+	collector, ok := h.(prometheus.Histogram)
+	if !ok {
+		t.Fatalf("observer is not a Histogram")
+	}
+	var m dto.Metric
+	if err := collector.Write(&m); err != nil {
+		t.Fatalf("failed to read histogram: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestIsPrunedBlockError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("Block not available (pruned data)"), true},
+		{fmt.Errorf("the requested block is PRUNED"), true},
+		{fmt.Errorf("connection refused"), false},
+	}
+	for _, c := range cases {
+		if got := isPrunedBlockError(c.err); got != c.want {
+			t.Errorf("isPrunedBlockError(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestPrunedFallbackClientWithNoPeersConfigured(t *testing.T) {
+	collector := metrics.NewCollector(prometheus.NewRegistry())
+	mockClient := &mockClient{
+		mockGetBlock: func(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+			return nil, fmt.Errorf("Block not available (pruned data)")
+		},
+	}
+
+	fallback := NewPrunedFallbackClient(mockClient, NewPrunedBlockDispatcher(nil, nil), collector)
+
+	hash := chainhash.HashH([]byte("pruned-fallback-test"))
+	if _, err := fallback.GetBlock(&hash); err == nil {
+		t.Fatal("expected an error when no fallback peers are configured")
+	}
+}
@@ -0,0 +1,236 @@
+package bitcoinrpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/pebbe/zmq4"
+
+	"github.com/tonyrishwain/btc-api/internal/metrics"
+)
+
+// BlockHandler is invoked for every block the notification client observes,
+// either from a ZMQ rawblock message or from gap reconciliation.
+type BlockHandler func(height int64, hash chainhash.Hash, block *wire.MsgBlock)
+
+// NotificationClient subscribes to bitcoind's ZMQ rawblock/hashblock/rawtx
+// publishers and turns incoming notifications into calls to onBlock,
+// replacing one-minute RPC polling with a push-based feed.
+type NotificationClient struct {
+	blockEndpoint string
+	txEndpoint    string
+	client        BitcoinClient
+	metrics       *metrics.Collector
+	onBlock       BlockHandler
+
+	lastHashBlockSeq uint32
+	lastRawBlockSeq  uint32
+	lastTxSeq        uint32
+	haveHashBlockSeq bool
+	haveRawBlockSeq  bool
+	haveTxSeq        bool
+	lastHeight       int64
+	haveLastHeight   bool
+}
+
+// NewNotificationClient builds a NotificationClient for the given ZMQ
+// endpoints. Endpoints are expected in zmq4's usual form, e.g.
+// "tcp://127.0.0.1:28332". client is used to resolve block heights and to
+// reconcile any blocks missed because of a ZMQ sequence gap.
+func NewNotificationClient(blockEndpoint, txEndpoint string, client BitcoinClient, m *metrics.Collector, onBlock BlockHandler) *NotificationClient {
+	return &NotificationClient{
+		blockEndpoint: blockEndpoint,
+		txEndpoint:    txEndpoint,
+		client:        client,
+		metrics:       m,
+		onBlock:       onBlock,
+	}
+}
+
+// Run connects to the configured ZMQ endpoints and processes notifications
+// until the process exits, reconnecting with exponential backoff (capped at
+// 60s) whenever the connection is lost.
+func (n *NotificationClient) Run() {
+	backoff := time.Second
+	const maxBackoff = 60 * time.Second
+
+	for {
+		if err := n.runOnce(); err != nil {
+			log.Printf("ZMQ notification client disconnected: %v", err)
+		}
+		n.metrics.BitcoinZMQConnectionStatus.Set(0)
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// runOnce dials the ZMQ endpoints and processes messages until a read fails,
+// at which point it returns the error so Run can reconnect.
+func (n *NotificationClient) runOnce() error {
+	sock, err := zmq4.NewSocket(zmq4.SUB)
+	if err != nil {
+		return fmt.Errorf("creating ZMQ socket: %w", err)
+	}
+	defer sock.Close()
+
+	if n.blockEndpoint != "" {
+		if err := sock.Connect(n.blockEndpoint); err != nil {
+			return fmt.Errorf("connecting to %s: %w", n.blockEndpoint, err)
+		}
+		sock.SetSubscribe("rawblock")
+		sock.SetSubscribe("hashblock")
+	}
+	if n.txEndpoint != "" && n.txEndpoint != n.blockEndpoint {
+		if err := sock.Connect(n.txEndpoint); err != nil {
+			return fmt.Errorf("connecting to %s: %w", n.txEndpoint, err)
+		}
+	}
+	if n.txEndpoint != "" {
+		sock.SetSubscribe("rawtx")
+	}
+
+	n.metrics.BitcoinZMQConnectionStatus.Set(1)
+
+	for {
+		parts, err := sock.RecvMessageBytes(0)
+		if err != nil {
+			return fmt.Errorf("receiving ZMQ message: %w", err)
+		}
+		if err := n.handleMessage(parts); err != nil {
+			log.Printf("Error handling ZMQ message: %v", err)
+		}
+	}
+}
+
+// handleMessage decodes a single ZMQ multipart message (topic, payload,
+// sequence number) and dispatches it by topic.
+func (n *NotificationClient) handleMessage(parts [][]byte) error {
+	if len(parts) != 3 {
+		return fmt.Errorf("unexpected ZMQ message with %d parts", len(parts))
+	}
+	topic := string(parts[0])
+	payload := parts[1]
+	seq := binary.LittleEndian.Uint32(parts[2])
+
+	switch topic {
+	case "hashblock":
+		return n.handleHashBlock(payload, seq)
+	case "rawblock":
+		return n.handleRawBlock(payload, seq)
+	case "rawtx":
+		return n.handleRawTx(payload, seq)
+	default:
+		return nil
+	}
+}
+
+// handleHashBlock tracks the hashblock sequence counter so gaps can be
+// detected; the heavier rawblock payload is what actually drives accounting.
+func (n *NotificationClient) handleHashBlock(payload []byte, seq uint32) error {
+	n.checkHashBlockGap(seq)
+	return nil
+}
+
+// handleRawBlock decodes a full serialized block and feeds it to onBlock,
+// reconciling any blocks skipped over a sequence gap first.
+func (n *NotificationClient) handleRawBlock(payload []byte, seq uint32) error {
+	gap := n.checkRawBlockGap(seq)
+
+	var block wire.MsgBlock
+	if err := block.Deserialize(bytes.NewReader(payload)); err != nil {
+		return fmt.Errorf("deserializing rawblock: %w", err)
+	}
+	hash := block.BlockHash()
+
+	height, err := n.client.GetBlockCount()
+	if err != nil {
+		return fmt.Errorf("resolving height for block %s: %w", hash, err)
+	}
+
+	if gap && n.haveLastHeight && n.lastHeight < height-1 {
+		log.Printf("ZMQ sequence gap detected, reconciling blocks %d-%d via RPC", n.lastHeight+1, height-1)
+		n.reconcileMissedBlocks(n.lastHeight, height-1)
+	}
+
+	n.onBlock(height, hash, &block)
+	n.lastHeight = height
+	n.haveLastHeight = true
+	return nil
+}
+
+// handleRawTx decodes a mempool transaction. It is observed but does not
+// currently feed into any gauge beyond sequence tracking.
+func (n *NotificationClient) handleRawTx(payload []byte, seq uint32) error {
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(payload)); err != nil {
+		return fmt.Errorf("deserializing rawtx: %w", err)
+	}
+	n.checkTxGap(seq)
+	return nil
+}
+
+// reconcileMissedBlocks fetches every block in (fromHeight, toHeight] via RPC
+// and feeds it through onBlock. It is used to backfill blocks that were
+// missed because of a ZMQ sequence gap.
+func (n *NotificationClient) reconcileMissedBlocks(fromHeight, toHeight int64) {
+	for height := fromHeight + 1; height <= toHeight; height++ {
+		blockHash, err := n.client.GetBlockHash(height)
+		if err != nil {
+			log.Printf("Error reconciling block hash for height %d: %v", height, err)
+			continue
+		}
+		block, err := n.client.GetBlock(blockHash)
+		if err != nil {
+			log.Printf("Error reconciling block for hash %s: %v", blockHash, err)
+			continue
+		}
+		n.onBlock(height, *blockHash, block)
+	}
+}
+
+// checkHashBlockGap records the last seen hashblock sequence number and
+// reports whether a jump indicates a missed notification. bitcoind keeps an
+// independent sequence counter per ZMQ topic, so this is tracked separately
+// from rawblock's.
+func (n *NotificationClient) checkHashBlockGap(seq uint32) (gap bool) {
+	gap = n.haveHashBlockSeq && seq != n.lastHashBlockSeq+1
+	if gap {
+		log.Printf("Detected gap in ZMQ hashblock sequence: %d -> %d", n.lastHashBlockSeq, seq)
+	}
+	n.lastHashBlockSeq = seq
+	n.haveHashBlockSeq = true
+	return gap
+}
+
+// checkRawBlockGap records the last seen rawblock sequence number and
+// reports whether a jump indicates a missed notification. bitcoind keeps an
+// independent sequence counter per ZMQ topic, so this is tracked separately
+// from hashblock's.
+func (n *NotificationClient) checkRawBlockGap(seq uint32) (gap bool) {
+	gap = n.haveRawBlockSeq && seq != n.lastRawBlockSeq+1
+	if gap {
+		log.Printf("Detected gap in ZMQ rawblock sequence: %d -> %d", n.lastRawBlockSeq, seq)
+	}
+	n.lastRawBlockSeq = seq
+	n.haveRawBlockSeq = true
+	return gap
+}
+
+// checkTxGap records the last seen rawtx sequence number and logs when a
+// jump indicates a missed notification.
+func (n *NotificationClient) checkTxGap(seq uint32) {
+	if n.haveTxSeq && seq != n.lastTxSeq+1 {
+		log.Printf("Detected gap in ZMQ rawtx sequence: %d -> %d", n.lastTxSeq, seq)
+	}
+	n.lastTxSeq = seq
+	n.haveTxSeq = true
+}
@@ -0,0 +1,124 @@
+package bitcoinrpc
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/peer"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// PrunedBlockDispatcher fetches individual blocks directly from Bitcoin P2P
+// peers, for use when a pruned bitcoind can no longer serve them over RPC.
+// Modeled on the peer block dispatcher used by the babylonchain vigilante
+// btc client.
+type PrunedBlockDispatcher struct {
+	peerAddrs   []string
+	chainParams *chaincfg.Params
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewPrunedBlockDispatcher builds a dispatcher that rotates requests across
+// peerAddrs (host:port form). A nil/empty peerAddrs disables fallback.
+func NewPrunedBlockDispatcher(peerAddrs []string, chainParams *chaincfg.Params) *PrunedBlockDispatcher {
+	return &PrunedBlockDispatcher{peerAddrs: peerAddrs, chainParams: chainParams}
+}
+
+// Healthy reports whether the dispatcher has any configured peers to fall
+// back to.
+func (d *PrunedBlockDispatcher) Healthy() bool {
+	return d != nil && len(d.peerAddrs) > 0
+}
+
+// PeerCount returns the number of configured fallback peers.
+func (d *PrunedBlockDispatcher) PeerCount() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.peerAddrs)
+}
+
+// nextPeer returns the next peer address to try, rotating through the
+// configured pool so repeated failures don't hammer a single bad peer.
+func (d *PrunedBlockDispatcher) nextPeer() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	addr := d.peerAddrs[d.next%len(d.peerAddrs)]
+	d.next++
+	return addr
+}
+
+// FetchBlock requests the block with the given hash from the configured
+// peers in turn, validating the returned block's hash before returning it.
+func (d *PrunedBlockDispatcher) FetchBlock(hash *chainhash.Hash) (*wire.MsgBlock, error) {
+	if len(d.peerAddrs) == 0 {
+		return nil, errors.New("no P2P peers configured for pruned block fallback")
+	}
+
+	var lastErr error
+	for i := 0; i < len(d.peerAddrs); i++ {
+		addr := d.nextPeer()
+		block, err := d.fetchFromPeer(addr, hash)
+		if err == nil {
+			return block, nil
+		}
+		log.Printf("Pruned block fallback: peer %s failed for block %s: %v", addr, hash, err)
+		lastErr = err
+	}
+	return nil, fmt.Errorf("all %d peers failed, last error: %w", len(d.peerAddrs), lastErr)
+}
+
+// fetchFromPeer dials a single peer, requests the block via getdata, and
+// waits for the matching block message.
+func (d *PrunedBlockDispatcher) fetchFromPeer(addr string, hash *chainhash.Hash) (*wire.MsgBlock, error) {
+	blockCh := make(chan *wire.MsgBlock, 1)
+
+	cfg := &peer.Config{
+		UserAgentName:    "btc-api-pruned-fallback",
+		UserAgentVersion: "1.0.0",
+		ChainParams:      d.chainParams,
+		Listeners: peer.MessageListeners{
+			OnBlock: func(p *peer.Peer, msg *wire.MsgBlock, buf []byte) {
+				if msg.BlockHash() == *hash {
+					select {
+					case blockCh <- msg:
+					default:
+					}
+				}
+			},
+		},
+	}
+
+	p, err := peer.NewOutboundPeer(cfg, addr)
+	if err != nil {
+		return nil, fmt.Errorf("creating peer for %s: %w", addr, err)
+	}
+
+	conn, err := net.DialTimeout("tcp", p.Addr(), 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("dialing %s: %w", addr, err)
+	}
+	p.AssociateConnection(conn)
+	defer p.Disconnect()
+
+	getData := wire.NewMsgGetData()
+	if err := getData.AddInvVect(wire.NewInvVect(wire.InvTypeBlock, hash)); err != nil {
+		return nil, fmt.Errorf("building getdata for %s: %w", hash, err)
+	}
+	p.QueueMessage(getData, nil)
+
+	select {
+	case block := <-blockCh:
+		return block, nil
+	case <-time.After(30 * time.Second):
+		return nil, fmt.Errorf("timed out waiting for block %s from %s", hash, addr)
+	}
+}
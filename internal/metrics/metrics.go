@@ -0,0 +1,253 @@
+// Package metrics owns every Prometheus collector the server exposes and the
+// ad-hoc human-readable summary served by /summary.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Collector owns all of the application's Prometheus collectors. Embedding
+// them in a struct (instead of package globals) lets each binary that wires
+// up the server choose its own prometheus.Registerer and makes the
+// collector embeddable in other programs.
+type Collector struct {
+	HTTPRequestsTotal   *prometheus.CounterVec
+	HTTPRequestDuration *prometheus.HistogramVec
+
+	BitcoinNodeBlockHeight      prometheus.Gauge
+	BitcoinNodeConnectionStatus prometheus.Gauge
+	BitcoinZMQConnectionStatus  prometheus.Gauge
+
+	TransactionsAboveThresholdTotal *prometheus.GaugeVec
+	BTCVolumeAboveThreshold         *prometheus.GaugeVec
+
+	BitcoinRPCLatency     *prometheus.HistogramVec
+	BitcoinRPCErrorsTotal *prometheus.CounterVec
+	BitcoinRPCInFlight    prometheus.Gauge
+
+	BitcoinBlockCacheHitsTotal   prometheus.Counter
+	BitcoinBlockCacheMissesTotal prometheus.Counter
+	BitcoinBlockCacheSize        prometheus.Gauge
+
+	BitcoinPrunedFallbackTotal       prometheus.Counter
+	BitcoinPrunedFallbackErrorsTotal prometheus.Counter
+}
+
+// NewCollector builds every collector and registers it against reg.
+func NewCollector(reg prometheus.Registerer) *Collector {
+	c := &Collector{
+		HTTPRequestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_requests_total",
+				Help: "Total number of HTTP requests",
+			},
+			[]string{"endpoint"},
+		),
+		HTTPRequestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_request_duration_seconds",
+				Help:    "Duration of HTTP requests in seconds",
+				Buckets: []float64{0.01, 0.1, 1},
+			},
+			[]string{"endpoint"},
+		),
+		BitcoinNodeBlockHeight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "bitcoin_node_block_height",
+			Help: "Current block height of the Bitcoin node",
+		}),
+		BitcoinNodeConnectionStatus: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "bitcoin_node_connection_status",
+			Help: "Connection status to the Bitcoin node (1 = connected, 0 = disconnected)",
+		}),
+		BitcoinZMQConnectionStatus: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "bitcoin_zmq_connection_status",
+			Help: "Connection status to the Bitcoin node's ZMQ publishers (1 = connected, 0 = disconnected)",
+		}),
+		TransactionsAboveThresholdTotal: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "transactions_above_threshold_total",
+				Help: "Total number of transactions above the threshold in the given rescan window",
+			},
+			[]string{"window"},
+		),
+		BTCVolumeAboveThreshold: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "btc_volume_above_threshold",
+				Help: "Total BTC volume of transactions above the threshold in the given rescan window",
+			},
+			[]string{"window"},
+		),
+		BitcoinRPCLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "bitcoin_rpc_latency_seconds",
+				Help:    "Latency of Bitcoin RPC calls in seconds",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"method"},
+		),
+		BitcoinRPCErrorsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "bitcoin_rpc_errors_total",
+				Help: "Total number of Bitcoin RPC errors",
+			},
+			[]string{"method", "class"},
+		),
+		BitcoinRPCInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "bitcoin_rpc_in_flight",
+			Help: "Number of Bitcoin RPC calls currently in flight",
+		}),
+		BitcoinBlockCacheHitsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "bitcoin_block_cache_hits_total",
+			Help: "Total number of block cache hits",
+		}),
+		BitcoinBlockCacheMissesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "bitcoin_block_cache_misses_total",
+			Help: "Total number of block cache misses",
+		}),
+		BitcoinBlockCacheSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "bitcoin_block_cache_size",
+			Help: "Current number of decoded blocks held in the block cache",
+		}),
+		BitcoinPrunedFallbackTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "bitcoin_pruned_fallback_total",
+			Help: "Total number of blocks successfully fetched from a P2P peer after the node reported them as pruned",
+		}),
+		BitcoinPrunedFallbackErrorsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "bitcoin_pruned_fallback_errors_total",
+			Help: "Total number of P2P peer fallback fetches that failed",
+		}),
+	}
+
+	reg.MustRegister(
+		c.HTTPRequestsTotal,
+		c.HTTPRequestDuration,
+		c.BitcoinNodeBlockHeight,
+		c.BitcoinNodeConnectionStatus,
+		c.BitcoinZMQConnectionStatus,
+		c.TransactionsAboveThresholdTotal,
+		c.BTCVolumeAboveThreshold,
+		c.BitcoinRPCLatency,
+		c.BitcoinRPCErrorsTotal,
+		c.BitcoinRPCInFlight,
+		c.BitcoinBlockCacheHitsTotal,
+		c.BitcoinBlockCacheMissesTotal,
+		c.BitcoinBlockCacheSize,
+		c.BitcoinPrunedFallbackTotal,
+		c.BitcoinPrunedFallbackErrorsTotal,
+	)
+
+	return c
+}
+
+// WindowLabel formats a block count as the "window" label value shared by
+// the threshold gauges, so multiple rescan windows (e.g. 25, 144, 1008) can
+// be scraped concurrently without clobbering each other.
+func WindowLabel(blocks int64) string {
+	return strconv.FormatInt(blocks, 10)
+}
+
+// FormatSummary renders the node status, transaction summary and RPC
+// collectors into the human-readable format served by /summary.
+func (c *Collector) FormatSummary() string {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		c.BitcoinNodeBlockHeight,
+		c.BitcoinNodeConnectionStatus,
+		c.TransactionsAboveThresholdTotal,
+		c.BTCVolumeAboveThreshold,
+		c.BitcoinRPCLatency,
+		c.BitcoinRPCErrorsTotal,
+		c.BitcoinRPCInFlight,
+	)
+
+	gathered, err := registry.Gather()
+	if err != nil {
+		return fmt.Sprintf("Error gathering metrics: %v\n", err)
+	}
+	return formatMetrics(gathered)
+}
+
+// formatMetrics organizes and formats the metrics into a human-readable string.
+func formatMetrics(metrics []*dto.MetricFamily) string {
+	var buffer bytes.Buffer
+
+	sections := []struct {
+		name    string
+		metrics []string
+	}{
+		{"Bitcoin Node Status", []string{"bitcoin_node_block_height", "bitcoin_node_connection_status"}},
+		{"Transaction Summary", []string{"transactions_above_threshold_total", "btc_volume_above_threshold"}},
+		{"RPC", []string{"bitcoin_rpc_latency_seconds", "bitcoin_rpc_errors_total", "bitcoin_rpc_in_flight"}},
+	}
+
+	for _, section := range sections {
+		fmt.Fprintf(&buffer, "# %s\n", section.name)
+		for _, metricName := range section.metrics {
+			for _, mf := range metrics {
+				if *mf.Name == metricName {
+					writeMetricFamily(&buffer, mf)
+				}
+			}
+		}
+		buffer.WriteString("\n")
+	}
+
+	return buffer.String()
+}
+
+// writeMetricFamily writes a single metric family to the buffer.
+func writeMetricFamily(buffer *bytes.Buffer, mf *dto.MetricFamily) {
+	for _, m := range mf.Metric {
+		switch *mf.Type {
+		case dto.MetricType_GAUGE:
+			writeGauge(buffer, mf.Name, m)
+		case dto.MetricType_COUNTER:
+			writeCounter(buffer, mf.Name, m)
+		case dto.MetricType_HISTOGRAM:
+			writeHistogram(buffer, mf.Name, m)
+		}
+	}
+}
+
+// writeGauge writes a single gauge metric to the buffer.
+func writeGauge(buffer *bytes.Buffer, name *string, m *dto.Metric) {
+	fmt.Fprintf(buffer, "%s%s %v\n", *name, labelsToString(m.Label), *m.Gauge.Value)
+}
+
+// writeCounter writes a single counter metric to the buffer.
+func writeCounter(buffer *bytes.Buffer, name *string, m *dto.Metric) {
+	fmt.Fprintf(buffer, "%s%s %v\n", *name, labelsToString(m.Label), *m.Counter.Value)
+}
+
+// writeHistogram writes a single histogram metric's count and sum to the
+// buffer; individual bucket counts are left to the raw /metrics endpoint.
+func writeHistogram(buffer *bytes.Buffer, name *string, m *dto.Metric) {
+	fmt.Fprintf(buffer, "%s_count%s %v\n", *name, labelsToString(m.Label), m.Histogram.GetSampleCount())
+	fmt.Fprintf(buffer, "%s_sum%s %v\n", *name, labelsToString(m.Label), m.Histogram.GetSampleSum())
+}
+
+// labelsToString converts metric labels to a string representation.
+func labelsToString(labels []*dto.LabelPair) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("{")
+	for i, label := range labels {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString(*label.Name)
+		buffer.WriteString("=\"")
+		buffer.WriteString(*label.Value)
+		buffer.WriteString("\"")
+	}
+	buffer.WriteString("}")
+	return buffer.String()
+}